@@ -0,0 +1,132 @@
+// Package client is an HTTP client for orcaq/server, offering PushBytes,
+// PushJobAt, PushUnique, GetJobByID, ListJobs and CancelJob like
+// orcaq.Queue. The method sets differ: Client has no PushJob, its PushJobAt
+// takes a payload rather than a *orcaq.Job, and its ListJobs returns
+// map[string]*orcaq.Job rather than map[string]interface{}.
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/OrcaTools/orcaq"
+)
+
+// Client talks to an orcaq/server instance over HTTP.
+type Client struct {
+	baseURL string
+	http    *http.Client
+}
+
+// New returns a Client targeting the orcaq/server listening at baseURL (e.g.
+// "http://localhost:8080").
+func New(baseURL string) *Client {
+	return &Client{baseURL: baseURL, http: http.DefaultClient}
+}
+
+type pushRequest struct {
+	Data     []byte     `json:"data"`
+	RunAt    *time.Time `json:"run_at,omitempty"`
+	Priority int        `json:"priority,omitempty"`
+	DedupKey string     `json:"dedup_key,omitempty"`
+}
+
+type pushResponse struct {
+	ID string `json:"id"`
+}
+
+// PushBytes pushes a job carrying data, eligible for dispatch immediately.
+func (c *Client) PushBytes(data []byte) ([]byte, error) {
+	return c.push(pushRequest{Data: data})
+}
+
+// PushJobAt pushes a job carrying data that isn't eligible for dispatch
+// until runAt.
+func (c *Client) PushJobAt(data []byte, runAt time.Time) ([]byte, error) {
+	return c.push(pushRequest{Data: data, RunAt: &runAt})
+}
+
+// PushUnique pushes a job carrying the given dedup key, guaranteeing at most
+// one job with that key is queued at a time. See orcaq.Queue.PushUnique.
+func (c *Client) PushUnique(key string, data []byte) ([]byte, error) {
+	return c.push(pushRequest{Data: data, DedupKey: key})
+}
+
+func (c *Client) push(req pushRequest) ([]byte, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.http.Post(c.baseURL+"/jobs", "application/json", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		return nil, fmt.Errorf("client: push job: unexpected status %s", resp.Status)
+	}
+
+	var pr pushResponse
+	if err := json.NewDecoder(resp.Body).Decode(&pr); err != nil {
+		return nil, err
+	}
+	return []byte(pr.ID), nil
+}
+
+// GetJobByID fetches the Job identified by id from the remote queue.
+func (c *Client) GetJobByID(id []byte) (*orcaq.Job, error) {
+	resp, err := c.http.Get(c.baseURL + "/jobs/" + string(id))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("client: get job %q: unexpected status %s", id, resp.Status)
+	}
+
+	var job orcaq.Job
+	if err := json.NewDecoder(resp.Body).Decode(&job); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// ListJobs fetches every job known to the remote queue, keyed by Job ID.
+func (c *Client) ListJobs() (map[string]*orcaq.Job, error) {
+	resp, err := c.http.Get(c.baseURL + "/jobs")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("client: list jobs: unexpected status %s", resp.Status)
+	}
+
+	jobs := map[string]*orcaq.Job{}
+	if err := json.NewDecoder(resp.Body).Decode(&jobs); err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+// CancelJob cancels the Job identified by id on the remote queue, marking it
+// Failed.
+func (c *Client) CancelJob(id []byte) error {
+	req, err := http.NewRequest(http.MethodDelete, c.baseURL+"/jobs/"+string(id), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		return fmt.Errorf("client: cancel job %q: unexpected status %s", id, resp.Status)
+	}
+	return nil
+}
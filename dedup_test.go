@@ -0,0 +1,101 @@
+package orcaq
+
+import (
+	"testing"
+
+	"github.com/OrcaTools/orcaq/memstore"
+)
+
+func TestPushUnique_DedupsWhilePending(t *testing.T) {
+	q, err := InitWithStore(memstore.New())
+	if err != nil {
+		t.Fatalf("InitWithStore: %v", err)
+	}
+	defer q.Close()
+
+	id1, err := q.PushUnique("rebuild-repo-x", []byte("first"))
+	if err != nil {
+		t.Fatalf("PushUnique: %v", err)
+	}
+	id2, err := q.PushUnique("rebuild-repo-x", []byte("second"))
+	if err != nil {
+		t.Fatalf("PushUnique: %v", err)
+	}
+	if string(id1) != string(id2) {
+		t.Fatalf("expected second PushUnique to return the existing job ID %q, got %q", id1, id2)
+	}
+
+	jobs, err := q.ListJobs()
+	if err != nil {
+		t.Fatalf("ListJobs: %v", err)
+	}
+	if len(jobs) != 1 {
+		t.Fatalf("expected exactly 1 job queued, got %d", len(jobs))
+	}
+}
+
+func TestPushJob_DedupsWhileBlockedOnDependency(t *testing.T) {
+	q, err := InitWithStore(memstore.New())
+	if err != nil {
+		t.Fatalf("InitWithStore: %v", err)
+	}
+	defer q.Close()
+
+	depID, err := q.PushBytes([]byte("dep"))
+	if err != nil {
+		t.Fatalf("PushBytes: %v", err)
+	}
+
+	id1, err := q.PushJob(&Job{Data: []byte("first"), DedupKey: "rebuild-repo-x", Dependencies: [][]byte{depID}})
+	if err != nil {
+		t.Fatalf("PushJob: %v", err)
+	}
+	job, err := q.GetJobByID(id1)
+	if err != nil {
+		t.Fatalf("GetJobByID: %v", err)
+	}
+	if job.Status != Blocked {
+		t.Fatalf("expected first job to start Blocked, got %v", job.Status)
+	}
+
+	id2, err := q.PushJob(&Job{Data: []byte("second"), DedupKey: "rebuild-repo-x", Dependencies: [][]byte{depID}})
+	if err != nil {
+		t.Fatalf("PushJob: %v", err)
+	}
+	if string(id1) != string(id2) {
+		t.Fatalf("expected PushJob to return the existing Blocked job's ID %q, got %q", id1, id2)
+	}
+
+	jobs, err := q.ListJobs()
+	if err != nil {
+		t.Fatalf("ListJobs: %v", err)
+	}
+	// depID plus the single deduped dependant.
+	if len(jobs) != 2 {
+		t.Fatalf("expected exactly 2 jobs queued (dep + 1 dependant), got %d", len(jobs))
+	}
+}
+
+func TestPushUnique_AllowsReuseAfterCompletion(t *testing.T) {
+	q, err := InitWithStore(memstore.New())
+	if err != nil {
+		t.Fatalf("InitWithStore: %v", err)
+	}
+	defer q.Close()
+
+	id1, err := q.PushUnique("rebuild-repo-x", []byte("first"))
+	if err != nil {
+		t.Fatalf("PushUnique: %v", err)
+	}
+	if err := q.updateJobStatus(id1, Ack, "done"); err != nil {
+		t.Fatalf("updateJobStatus: %v", err)
+	}
+
+	id2, err := q.PushUnique("rebuild-repo-x", []byte("second"))
+	if err != nil {
+		t.Fatalf("PushUnique: %v", err)
+	}
+	if string(id1) == string(id2) {
+		t.Fatal("expected a fresh job once the dedup key's holder completed, got the same ID back")
+	}
+}
@@ -0,0 +1,51 @@
+package orcaq
+
+import "time"
+
+// dependencyFailedMessage is the canonical Message set on a Job that is
+// auto-failed because one of its Dependencies reached Failed.
+const dependencyFailedMessage = "dependency failed"
+
+// addDependants records that the Jobs identified by depIDs each have id as a
+// dependant, so that when a dependency Acks or Fails, id can be looked up and
+// re-evaluated.
+func (q *Queue) addDependants(id []byte, depIDs [][]byte) {
+	q.dependantsMu.Lock()
+	defer q.dependantsMu.Unlock()
+	for _, depID := range depIDs {
+		key := string(depID)
+		q.dependants[key] = append(q.dependants[key], string(id))
+	}
+}
+
+// handleDependencyAck decrements dependantID's PendingDeps count and, once it
+// reaches zero, flips the Job from Blocked to Uack and schedules it.
+func (q *Queue) handleDependencyAck(dependantID []byte) {
+	var unblocked bool
+	var runAt time.Time
+	var priority int
+	err := q.store.Update(func(tx Tx) error {
+		v, err := tx.Get(jobsBucketName, dependantID)
+		if err != nil {
+			return err
+		}
+		job := DecodeJob(v)
+		if job.PendingDeps > 0 {
+			job.PendingDeps--
+		}
+		if job.Status == Blocked && job.PendingDeps == 0 {
+			job.Status = Uack
+			unblocked = true
+			runAt = job.RunAt
+			priority = job.Priority
+		}
+		return tx.Put(jobsBucketName, job.ID, job.Bytes())
+	})
+	if err != nil {
+		logger().Printf("Unable to update dependant job %v after dependency ack: %s", string(dependantID), err)
+		return
+	}
+	if unblocked {
+		q.schedule(dependantID, runAt, priority)
+	}
+}
@@ -0,0 +1,175 @@
+package orcaq
+
+import (
+	"testing"
+
+	"github.com/OrcaTools/orcaq/memstore"
+)
+
+func TestPushJob_BlocksUntilDependencyAcks(t *testing.T) {
+	q, err := InitWithStore(memstore.New())
+	if err != nil {
+		t.Fatalf("InitWithStore: %v", err)
+	}
+	defer q.Close()
+
+	depID, err := q.PushBytes([]byte("dep"))
+	if err != nil {
+		t.Fatalf("PushBytes: %v", err)
+	}
+
+	id, err := q.PushJob(&Job{Data: []byte("dependant"), Dependencies: [][]byte{depID}})
+	if err != nil {
+		t.Fatalf("PushJob: %v", err)
+	}
+
+	job, err := q.GetJobByID(id)
+	if err != nil {
+		t.Fatalf("GetJobByID: %v", err)
+	}
+	if job.Status != Blocked {
+		t.Fatalf("expected dependant job to start Blocked, got %v", job.Status)
+	}
+
+	if err := q.updateJobStatus(depID, Ack, "done"); err != nil {
+		t.Fatalf("updateJobStatus: %v", err)
+	}
+
+	job, err = q.GetJobByID(id)
+	if err != nil {
+		t.Fatalf("GetJobByID: %v", err)
+	}
+	if job.Status != Uack {
+		t.Fatalf("expected dependant job to unblock to Uack once its dependency Acked, got %v", job.Status)
+	}
+}
+
+func TestPushJob_FailsWhenDependencyFails(t *testing.T) {
+	q, err := InitWithStore(memstore.New())
+	if err != nil {
+		t.Fatalf("InitWithStore: %v", err)
+	}
+	defer q.Close()
+
+	depID, err := q.PushBytes([]byte("dep"))
+	if err != nil {
+		t.Fatalf("PushBytes: %v", err)
+	}
+	if err := q.updateJobStatus(depID, Failed, "boom"); err != nil {
+		t.Fatalf("updateJobStatus: %v", err)
+	}
+
+	id, err := q.PushJob(&Job{Data: []byte("dependant"), Dependencies: [][]byte{depID}})
+	if err != nil {
+		t.Fatalf("PushJob: %v", err)
+	}
+
+	job, err := q.GetJobByID(id)
+	if err != nil {
+		t.Fatalf("GetJobByID: %v", err)
+	}
+	if job.Status != Failed {
+		t.Fatalf("expected dependant job to auto-fail, got %v", job.Status)
+	}
+	if job.Message != dependencyFailedMessage {
+		t.Fatalf("expected dependency-failed message, got %q", job.Message)
+	}
+}
+
+// TestProcessJobs_FailsBlockedJobWhoseDependencyFailedInSameBatch exercises
+// processJobs' Blocked-job rebuild on Init, where a dependency reaching
+// Failed is loaded (and tombstoned/deleted) in the very same pass as its
+// still-Blocked dependant.
+func TestProcessJobs_FailsBlockedJobWhoseDependencyFailedInSameBatch(t *testing.T) {
+	s := memstore.New()
+	q, err := InitWithStore(s)
+	if err != nil {
+		t.Fatalf("InitWithStore: %v", err)
+	}
+
+	depID, err := q.PushBytes([]byte("dep"))
+	if err != nil {
+		t.Fatalf("PushBytes: %v", err)
+	}
+	id, err := q.PushJob(&Job{Data: []byte("dependant"), Dependencies: [][]byte{depID}})
+	if err != nil {
+		t.Fatalf("PushJob: %v", err)
+	}
+	if err := q.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	// Mark the dependency Failed directly in the store, as if a separate
+	// process had done so while this Queue wasn't running to notify the
+	// dependant live via its in-memory dependants map.
+	err = s.Update(func(tx Tx) error {
+		v, err := tx.Get(jobsBucketName, depID)
+		if err != nil {
+			return err
+		}
+		dep := DecodeJob(v)
+		dep.Status = Failed
+		dep.Message = "boom"
+		return tx.Put(jobsBucketName, dep.ID, dep.Bytes())
+	})
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+
+	// Re-Init against the same store: processJobs rebuilds the Blocked job
+	// and the Failed dependency in the same pass.
+	q, err = InitWithStore(s)
+	if err != nil {
+		t.Fatalf("InitWithStore: %v", err)
+	}
+	defer q.Close()
+
+	job, err := q.GetJobByID(id)
+	if err != nil {
+		t.Fatalf("GetJobByID: %v", err)
+	}
+	if job.Status != Failed {
+		t.Fatalf("expected Blocked job to fail once its dependency's Failed status is rebuilt, got %v", job.Status)
+	}
+}
+
+// TestPushJob_ConsultsTombstoneAfterDependencyIsPruned exercises the restart
+// path: processJobs deletes a dependency's Jobs row once it reaches a
+// terminal status, so a dependant pushed after a restart has only the
+// tombstone left to consult.
+func TestPushJob_ConsultsTombstoneAfterDependencyIsPruned(t *testing.T) {
+	s := memstore.New()
+	q, err := InitWithStore(s)
+	if err != nil {
+		t.Fatalf("InitWithStore: %v", err)
+	}
+
+	depID, err := q.PushBytes([]byte("dep"))
+	if err != nil {
+		t.Fatalf("PushBytes: %v", err)
+	}
+	if err := q.updateJobStatus(depID, Failed, "boom"); err != nil {
+		t.Fatalf("updateJobStatus: %v", err)
+	}
+	if err := q.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	q, err = InitWithStore(s)
+	if err != nil {
+		t.Fatalf("InitWithStore: %v", err)
+	}
+	defer q.Close()
+
+	id, err := q.PushJob(&Job{Data: []byte("dependant"), Dependencies: [][]byte{depID}})
+	if err != nil {
+		t.Fatalf("PushJob: %v", err)
+	}
+	job, err := q.GetJobByID(id)
+	if err != nil {
+		t.Fatalf("GetJobByID: %v", err)
+	}
+	if job.Status != Failed {
+		t.Fatalf("expected dependant job to fail based on the pruned dependency's tombstone, got %v", job.Status)
+	}
+}
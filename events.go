@@ -0,0 +1,81 @@
+package orcaq
+
+// JobEvent describes a single Job status transition. Subscribers receive at
+// most one JobEvent per call to updateJobStatus/nackJob, in the order they
+// occur; an event is dropped for a subscriber whose channel buffer is full.
+type JobEvent struct {
+	JobID   []byte
+	Status  JobStatus
+	Message string
+}
+
+// eventSub is one SubscribeJobEvents registration.
+type eventSub struct {
+	ch chan JobEvent
+}
+
+// SubscribeJobEvents returns a channel delivering JobEvents for id as they
+// happen, and a cancel func the caller must call once it stops listening.
+// The channel is closed once the job reaches a terminal status (Ack or
+// Failed) or cancel is called, whichever happens first. orcaq/server uses
+// this to back its SSE job-events endpoint.
+func (q *Queue) SubscribeJobEvents(id []byte) (<-chan JobEvent, func()) {
+	sub := &eventSub{ch: make(chan JobEvent, 16)}
+	key := string(id)
+
+	q.eventSubsMu.Lock()
+	q.eventSubs[key] = append(q.eventSubs[key], sub)
+	q.eventSubsMu.Unlock()
+
+	var closeOnce bool
+	cancel := func() {
+		q.eventSubsMu.Lock()
+		defer q.eventSubsMu.Unlock()
+		if closeOnce {
+			return
+		}
+		subs := q.eventSubs[key]
+		found := false
+		for i, s := range subs {
+			if s == sub {
+				q.eventSubs[key] = append(subs[:i], subs[i+1:]...)
+				found = true
+				break
+			}
+		}
+		if !found {
+			// already removed (and closed) by publishJobEvent delivering a
+			// terminal status; nothing left to do.
+			return
+		}
+		closeOnce = true
+		close(sub.ch)
+	}
+	return sub.ch, cancel
+}
+
+// publishJobEvent notifies any subscribers of id about a status transition.
+// A terminal status (Ack/Failed) closes and clears out the subscriptions
+// after delivery.
+func (q *Queue) publishJobEvent(id []byte, status JobStatus, message string) {
+	key := string(id)
+	terminal := status == Ack || status == Failed
+
+	q.eventSubsMu.Lock()
+	subs := q.eventSubs[key]
+	if terminal {
+		delete(q.eventSubs, key)
+	}
+	q.eventSubsMu.Unlock()
+
+	for _, sub := range subs {
+		select {
+		case sub.ch <- JobEvent{JobID: id, Status: status, Message: message}:
+		default:
+			// slow subscriber; drop the event rather than block job processing
+		}
+		if terminal {
+			close(sub.ch)
+		}
+	}
+}
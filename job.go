@@ -0,0 +1,81 @@
+package orcaq
+
+import (
+	"bytes"
+	"encoding/gob"
+	"time"
+)
+
+// JobStatus represents the current lifecycle state of a Job
+type JobStatus int
+
+const (
+	// Uack indicates a job has been queued but not yet acknowledged by a worker
+	Uack JobStatus = iota
+	// Nack indicates a worker picked up the job but it needs to be retried
+	Nack
+	// Ack indicates a job completed successfully
+	Ack
+	// Failed indicates a job failed permanently and will not be retried
+	Failed
+	// Blocked indicates a job is waiting on one or more Dependencies to Ack
+	// before it becomes eligible for dispatch
+	Blocked
+)
+
+// Job represents a single unit of work to be processed by a Worker
+type Job struct {
+	// ID uniquely identifies a Job. It is assigned by Queue.PushJob; any
+	// caller-provided value is overwritten.
+	ID []byte
+	// Data is the arbitrary payload passed to a Worker's DoWork
+	Data []byte
+	// Status is the current lifecycle state of the Job
+	Status JobStatus
+	// Message holds the most recent status detail (e.g. an error, or "Complete")
+	Message string
+	// RetryCount is incremented each time the Job is Nack'd
+	RetryCount int
+	// RunAt is the earliest time the Job becomes eligible for dispatch.
+	// Zero means eligible immediately.
+	RunAt time.Time
+	// Priority orders jobs that are due at the same time; higher values are
+	// dispatched first.
+	Priority int
+	// DedupKey, when set, guarantees at most one Job with this key is present
+	// in the queue (in Uack, Nack or Blocked status) at a time. See
+	// Queue.PushUnique.
+	DedupKey string
+	// Dependencies lists the IDs of Jobs that must reach Ack before this Job
+	// becomes runnable. A Job with unresolved Dependencies is pushed with
+	// Status Blocked.
+	Dependencies [][]byte
+	// PendingDeps is the number of Dependencies that have not yet reached Ack.
+	// It is decremented as dependencies complete and the Job is unblocked
+	// (moved to Uack) once it reaches zero.
+	PendingDeps int
+	// RetryPolicy, if set, overrides the Queue's RetryPolicy for this Job.
+	RetryPolicy *RetryPolicy
+	// DispatchedAt records when the Job was first picked up by a worker
+	// (moved to Uack). It is used to compute the job-duration Metric once
+	// the Job reaches a terminal status.
+	DispatchedAt time.Time
+}
+
+// Bytes gob-encodes the Job for storage
+func (j *Job) Bytes() []byte {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(j); err != nil {
+		logger().Printf("Unable to encode job: %s", err)
+	}
+	return buf.Bytes()
+}
+
+// DecodeJob decodes a Job previously encoded with Job.Bytes
+func DecodeJob(b []byte) *Job {
+	var j Job
+	if err := gob.NewDecoder(bytes.NewReader(b)).Decode(&j); err != nil {
+		logger().Printf("Unable to decode job: %s", err)
+	}
+	return &j
+}
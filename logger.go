@@ -0,0 +1,62 @@
+package orcaq
+
+import (
+	"sync/atomic"
+
+	stdlog "github.com/sirupsen/logrus"
+)
+
+// Logger is the logging surface orcaq uses internally. Consumers that don't
+// want logrus in their dependency tree can call SetLogger with their own
+// implementation; the zero-value default logs through logrus, matching
+// orcaq's original behavior.
+type Logger interface {
+	Print(args ...interface{})
+	Printf(format string, args ...interface{})
+	Infof(format string, args ...interface{})
+	Errorf(format string, args ...interface{})
+}
+
+// loggerBox is the fixed concrete type stored in loggerValue. atomic.Value
+// requires every Store to use the same concrete type, but Logger is an
+// interface and SetLogger accepts any implementation of it; boxing it keeps
+// the stored type constant regardless of which Logger is in effect.
+type loggerBox struct {
+	l Logger
+}
+
+// loggerValue holds the package-wide Logger used by orcaq (and, through
+// CurrentLogger, by orcaq/server), behind an atomic.Value so SetLogger can safely
+// race with the dispatcher/worker/orphan-scan goroutines that log through
+// it concurrently.
+var loggerValue atomic.Value
+
+func init() {
+	loggerValue.Store(loggerBox{l: logrusLogger{}})
+}
+
+// logger returns the Logger currently in effect.
+func logger() Logger {
+	return loggerValue.Load().(loggerBox).l
+}
+
+// SetLogger replaces the Logger orcaq uses for its internal diagnostics. It
+// is safe to call concurrently with queue operations.
+func SetLogger(l Logger) {
+	loggerValue.Store(loggerBox{l: l})
+}
+
+// CurrentLogger returns the Logger orcaq is currently using, so related
+// packages (e.g. orcaq/server) can log consistently with it.
+func CurrentLogger() Logger {
+	return logger()
+}
+
+// logrusLogger is the default Logger, forwarding to logrus's standard
+// logger.
+type logrusLogger struct{}
+
+func (logrusLogger) Print(args ...interface{})                 { stdlog.Print(args...) }
+func (logrusLogger) Printf(format string, args ...interface{}) { stdlog.Printf(format, args...) }
+func (logrusLogger) Infof(format string, args ...interface{})  { stdlog.Infof(format, args...) }
+func (logrusLogger) Errorf(format string, args ...interface{}) { stdlog.Errorf(format, args...) }
@@ -0,0 +1,32 @@
+package orcaq
+
+import "testing"
+
+type noopLoggerA struct{}
+
+func (noopLoggerA) Print(args ...interface{})                 {}
+func (noopLoggerA) Printf(format string, args ...interface{}) {}
+func (noopLoggerA) Infof(format string, args ...interface{})  {}
+func (noopLoggerA) Errorf(format string, args ...interface{}) {}
+
+type noopLoggerB struct{}
+
+func (noopLoggerB) Print(args ...interface{})                 {}
+func (noopLoggerB) Printf(format string, args ...interface{}) {}
+func (noopLoggerB) Infof(format string, args ...interface{})  {}
+func (noopLoggerB) Errorf(format string, args ...interface{}) {}
+
+// TestSetLogger_AcceptsDifferentConcreteTypes guards against storing Logger
+// values directly in loggerValue: atomic.Value panics if two Stores use
+// different concrete types, which SetLogger's signature does nothing to
+// prevent.
+func TestSetLogger_AcceptsDifferentConcreteTypes(t *testing.T) {
+	defer SetLogger(logrusLogger{})
+
+	SetLogger(noopLoggerA{})
+	SetLogger(noopLoggerB{})
+
+	if _, ok := CurrentLogger().(noopLoggerB); !ok {
+		t.Fatalf("expected CurrentLogger to return the most recently set Logger")
+	}
+}
@@ -0,0 +1,127 @@
+// Package memstore is an in-memory orcaq.Store implementation. It has no
+// external dependencies, so it is the Store used by orcaq's own tests;
+// nothing written to it survives past the process.
+package memstore
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/OrcaTools/orcaq/store"
+)
+
+type memStore struct {
+	mu      sync.RWMutex
+	buckets map[string]map[string][]byte
+}
+
+// New returns an empty in-memory Store.
+func New() store.Store {
+	return &memStore{buckets: make(map[string]map[string][]byte)}
+}
+
+func (s *memStore) bucket(name string) map[string][]byte {
+	b, ok := s.buckets[name]
+	if !ok {
+		b = make(map[string][]byte)
+		s.buckets[name] = b
+	}
+	return b
+}
+
+func (s *memStore) Get(bucket string, key []byte) ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	v, ok := s.buckets[bucket][string(key)]
+	if !ok {
+		return nil, fmt.Errorf("memstore: key %q not found in bucket %q", key, bucket)
+	}
+	return v, nil
+}
+
+func (s *memStore) Put(bucket string, key []byte, value []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.bucket(bucket)[string(key)] = value
+	return nil
+}
+
+func (s *memStore) Delete(bucket string, key []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.bucket(bucket), string(key))
+	return nil
+}
+
+func (s *memStore) List(bucket string) ([][]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	b := s.buckets[bucket]
+	values := make([][]byte, 0, len(b))
+	for _, v := range b {
+		values = append(values, v)
+	}
+	return values, nil
+}
+
+// Update takes the Store's write lock for the duration of fn, giving fn the
+// same read-your-writes isolation nutsdb's transactions provide. If fn
+// returns an error, the buckets are restored to their state before fn ran.
+func (s *memStore) Update(fn func(tx store.Tx) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	snapshot := make(map[string]map[string][]byte, len(s.buckets))
+	for name, b := range s.buckets {
+		cp := make(map[string][]byte, len(b))
+		for k, v := range b {
+			cp[k] = v
+		}
+		snapshot[name] = cp
+	}
+	if err := fn(&memTx{s: s}); err != nil {
+		s.buckets = snapshot
+		return err
+	}
+	return nil
+}
+
+func (s *memStore) View(fn func(tx store.Tx) error) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return fn(&memTx{s: s})
+}
+
+func (s *memStore) Close() error { return nil }
+
+// memTx lets Update/View callbacks operate directly on the already-locked
+// Store's buckets.
+type memTx struct {
+	s *memStore
+}
+
+func (t *memTx) Get(bucket string, key []byte) ([]byte, error) {
+	v, ok := t.s.buckets[bucket][string(key)]
+	if !ok {
+		return nil, fmt.Errorf("memstore: key %q not found in bucket %q", key, bucket)
+	}
+	return v, nil
+}
+
+func (t *memTx) Put(bucket string, key []byte, value []byte) error {
+	t.s.bucket(bucket)[string(key)] = value
+	return nil
+}
+
+func (t *memTx) Delete(bucket string, key []byte) error {
+	delete(t.s.bucket(bucket), string(key))
+	return nil
+}
+
+func (t *memTx) List(bucket string) ([][]byte, error) {
+	b := t.s.buckets[bucket]
+	values := make([][]byte, 0, len(b))
+	for _, v := range b {
+		values = append(values, v)
+	}
+	return values, nil
+}
@@ -0,0 +1,43 @@
+package orcaq
+
+import "time"
+
+// Metrics is the instrumentation surface orcaq reports job lifecycle
+// counters, durations and gauges to. A Queue created by Init or
+// InitWithStore uses noopMetrics; see orcaq/promexporter for a
+// Prometheus-backed implementation.
+type Metrics interface {
+	// IncJobsPushed counts a Job successfully enqueued by PushJob.
+	IncJobsPushed()
+	// IncJobsAcked counts a Job that reached Ack.
+	IncJobsAcked()
+	// IncJobsNacked counts a Job that reported a recoverable error, whether
+	// or not it goes on to be retried or fail out.
+	IncJobsNacked()
+	// IncJobsFailed counts a Job that reached Failed, whether from a
+	// permanent worker error, a dependency failure, or exhausting its
+	// RetryPolicy.
+	IncJobsFailed()
+	// IncJobsRetried counts a Job rescheduled after a Nack.
+	IncJobsRetried()
+	// ObserveJobDuration records the time from a Job being picked up by a
+	// worker (Uack) to it reaching a terminal status (Ack or Failed).
+	ObserveJobDuration(d time.Duration)
+	// SetQueueDepth reports how many jobs currently hold status.
+	SetQueueDepth(status JobStatus, depth int)
+	// SetInFlight reports how many jobs a given Worker (by ID) is currently
+	// processing.
+	SetInFlight(workerID string, count int)
+}
+
+// noopMetrics is the default Metrics, discarding everything reported to it.
+type noopMetrics struct{}
+
+func (noopMetrics) IncJobsPushed()                     {}
+func (noopMetrics) IncJobsAcked()                      {}
+func (noopMetrics) IncJobsNacked()                     {}
+func (noopMetrics) IncJobsFailed()                     {}
+func (noopMetrics) IncJobsRetried()                    {}
+func (noopMetrics) ObserveJobDuration(d time.Duration) {}
+func (noopMetrics) SetQueueDepth(status JobStatus, depth int) {}
+func (noopMetrics) SetInFlight(workerID string, count int)    {}
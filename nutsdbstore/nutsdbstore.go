@@ -0,0 +1,115 @@
+// Package nutsdbstore is the original orcaq.Store implementation, backed by
+// an embedded nutsdb database file.
+package nutsdbstore
+
+import (
+	"github.com/xujiajun/nutsdb"
+
+	"github.com/OrcaTools/orcaq/store"
+)
+
+// nutsdbStore adapts a *nutsdb.DB to the store.Store interface
+type nutsdbStore struct {
+	db *nutsdb.DB
+}
+
+// New opens (creating if necessary) a nutsdb database rooted at path and
+// returns it as a store.Store. path cannot be shared between instances.
+func New(path string) (store.Store, error) {
+	opt := nutsdb.DefaultOptions
+	opt.Dir = path
+	db, err := nutsdb.Open(opt)
+	if err != nil {
+		return nil, err
+	}
+	return &nutsdbStore{db: db}, nil
+}
+
+func (s *nutsdbStore) Get(bucket string, key []byte) ([]byte, error) {
+	var value []byte
+	err := s.db.View(func(tx *nutsdb.Tx) error {
+		e, err := tx.Get(bucket, key)
+		if err != nil {
+			return err
+		}
+		value = e.Value
+		return nil
+	})
+	return value, err
+}
+
+func (s *nutsdbStore) Put(bucket string, key []byte, value []byte) error {
+	return s.db.Update(func(tx *nutsdb.Tx) error {
+		return tx.Put(bucket, key, value, 0) // 0 means never expires
+	})
+}
+
+func (s *nutsdbStore) Delete(bucket string, key []byte) error {
+	return s.db.Update(func(tx *nutsdb.Tx) error {
+		return tx.Delete(bucket, key)
+	})
+}
+
+func (s *nutsdbStore) List(bucket string) ([][]byte, error) {
+	var values [][]byte
+	err := s.db.View(func(tx *nutsdb.Tx) error {
+		entries, err := tx.GetAll(bucket)
+		if err != nil {
+			return err
+		}
+		for _, e := range entries {
+			values = append(values, e.Value)
+		}
+		return nil
+	})
+	return values, err
+}
+
+func (s *nutsdbStore) Update(fn func(tx store.Tx) error) error {
+	return s.db.Update(func(tx *nutsdb.Tx) error {
+		return fn(&nutsdbTx{tx: tx})
+	})
+}
+
+func (s *nutsdbStore) View(fn func(tx store.Tx) error) error {
+	return s.db.View(func(tx *nutsdb.Tx) error {
+		return fn(&nutsdbTx{tx: tx})
+	})
+}
+
+func (s *nutsdbStore) Close() error {
+	return s.db.Close()
+}
+
+// nutsdbTx adapts a *nutsdb.Tx to the store.Tx interface
+type nutsdbTx struct {
+	tx *nutsdb.Tx
+}
+
+func (t *nutsdbTx) Get(bucket string, key []byte) ([]byte, error) {
+	e, err := t.tx.Get(bucket, key)
+	if err != nil {
+		return nil, err
+	}
+	return e.Value, nil
+}
+
+func (t *nutsdbTx) Put(bucket string, key []byte, value []byte) error {
+	return t.tx.Put(bucket, key, value, 0)
+}
+
+func (t *nutsdbTx) Delete(bucket string, key []byte) error {
+	return t.tx.Delete(bucket, key)
+}
+
+func (t *nutsdbTx) List(bucket string) ([][]byte, error) {
+	entries, err := t.tx.GetAll(bucket)
+	if err != nil {
+		return nil, err
+	}
+	values := make([][]byte, 0, len(entries))
+	for _, e := range entries {
+		values = append(values, e.Value)
+	}
+	return values, nil
+}
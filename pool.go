@@ -0,0 +1,223 @@
+package orcaq
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// workerPool binds a Worker to a semaphore bounding how many of its DoWork
+// calls may run at once.
+type workerPool struct {
+	worker Worker
+	sem    chan struct{}
+}
+
+//RegisterWorker registers a Worker to handle queued Jobs with a concurrency
+//of 1. It is equivalent to RegisterWorkerPool(w, 1).
+func (q *Queue) RegisterWorker(w Worker) {
+	q.RegisterWorkerPool(w, 1)
+}
+
+//RegisterWorkerPool registers a Worker to handle queued Jobs, running up to
+//concurrency instances of its DoWork in parallel. Jobs are handed to it (and
+//any other registered pools) by a single central dispatcher goroutine; if
+//every pool is already at capacity, the dispatcher blocks rather than
+//dropping the job, providing backpressure.
+func (q *Queue) RegisterWorkerPool(w Worker, concurrency int) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	logger().Printf("Registering worker pool %s with concurrency %d", w.ID(), concurrency)
+	pool := &workerPool{worker: w, sem: make(chan struct{}, concurrency)}
+	q.poolsMu.Lock()
+	q.pools = append(q.pools, pool)
+	q.poolsMu.Unlock()
+}
+
+//runWorkDispatcher owns q.notifier and, for each job ID it receives, blocks
+//until some registered worker pool has capacity and hands the job off to it.
+//It exits once ctx is cancelled.
+func (q *Queue) runWorkDispatcher(ctx context.Context) {
+	defer q.wg.Done()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case jobID := <-q.notifier:
+			pool := q.acquirePool(ctx)
+			if pool == nil {
+				// ctx was cancelled while waiting for capacity
+				return
+			}
+			q.wg.Add(1)
+			go q.runJob(ctx, pool, jobID)
+		}
+	}
+}
+
+//acquirePool blocks until one of the registered worker pools has a free
+//slot, reserves it, and returns that pool. It returns nil if ctx is
+//cancelled first. While no pools are registered yet, it rechecks every
+//PollRate until one is.
+func (q *Queue) acquirePool(ctx context.Context) *workerPool {
+	for {
+		q.poolsMu.Lock()
+		pools := make([]*workerPool, len(q.pools))
+		copy(pools, q.pools)
+		q.poolsMu.Unlock()
+
+		if len(pools) == 0 {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(q.PollRate):
+				continue
+			}
+		}
+
+		cases := make([]reflect.SelectCase, 0, len(pools)+1)
+		for _, p := range pools {
+			cases = append(cases, reflect.SelectCase{
+				Dir:  reflect.SelectSend,
+				Chan: reflect.ValueOf(p.sem),
+				Send: reflect.ValueOf(struct{}{}),
+			})
+		}
+		cases = append(cases, reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ctx.Done())})
+
+		chosen, _, _ := reflect.Select(cases)
+		if chosen == len(pools) {
+			return nil
+		}
+		return pools[chosen]
+	}
+}
+
+//runJob processes a single job on behalf of pool, releasing its reserved
+//semaphore slot when done.
+func (q *Queue) runJob(ctx context.Context, pool *workerPool, jobID []byte) {
+	defer q.wg.Done()
+	defer func() { <-pool.sem }()
+
+	ctx, dispatchSpan := q.Tracer.Start(ctx, "orcaq.dispatch")
+	defer dispatchSpan.End()
+
+	if job, err := q.GetJobByID(jobID); err == nil && (job.Status == Ack || job.Status == Failed) {
+		// cancelled (or otherwise finished) while waiting to be dispatched
+		q.unschedule(jobID)
+		logger().Printf("Skipping job %v: already finished (%s)", string(jobID), job.Message)
+		return
+	}
+
+	logger().Printf("Dispatching job id %v to %s", string(jobID), pool.worker.ID())
+	err := q.updateJobStatus(jobID, Uack, fmt.Sprintf("Picked up by %s", pool.worker.ID()))
+	if err != nil {
+		logger().Printf("Unable to update job status: %s", err)
+		return
+	}
+	//If subsequent calls to updateJobStatus fail, the whole thing is probably hosed and
+	//it should probably do something more drastic for error handling.
+	job, err := q.GetJobByID(jobID)
+	if err != nil {
+		logger().Printf("Error processing job: %s", err)
+		q.updateJobStatus(jobID, Failed, err.Error())
+		return
+	}
+
+	q.inFlightMu.Lock()
+	q.inFlight[string(jobID)] = struct{}{}
+	q.inFlightMu.Unlock()
+	q.unschedule(jobID)
+	q.Metrics.SetInFlight(pool.worker.ID(), len(pool.sem))
+	defer func() {
+		q.inFlightMu.Lock()
+		delete(q.inFlight, string(jobID))
+		q.inFlightMu.Unlock()
+		q.Metrics.SetInFlight(pool.worker.ID(), len(pool.sem)-1)
+	}()
+
+	// Call the worker func handling this job
+	workCtx, workSpan := q.Tracer.Start(ctx, "orcaq.do_work")
+	err = pool.worker.DoWork(workCtx, job)
+	workSpan.End()
+	if err != nil {
+		rwErr, ok := err.(RecoverableWorkerError)
+		if ok {
+			//temporary error, retry (subject to the queue/job RetryPolicy)
+			logger().Printf("Received temporary error: %s. Retrying...", err.Error())
+			var retryAfter *time.Duration
+			if rwErr.RetryAfter > 0 {
+				retryAfter = &rwErr.RetryAfter
+			}
+			q.nackJob(jobID, err.Error(), retryAfter)
+		} else {
+			logger().Printf("Permanent error received from worker: %s", err)
+			//permanent error, mark as failed
+			q.updateJobStatus(jobID, Failed, err.Error())
+		}
+	} else {
+		q.updateJobStatus(jobID, Ack, "Complete")
+	}
+	logger().Printf("Finished processing job %v", string(jobID))
+}
+
+//runOrphanScan periodically re-enqueues jobs stuck in Uack that aren't
+//actually being worked on, e.g. because the worker processing them crashed.
+//It runs every q.PollRate until ctx is cancelled.
+func (q *Queue) runOrphanScan(ctx context.Context) {
+	defer q.wg.Done()
+	ticker := time.NewTicker(q.PollRate)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			q.reenqueueOrphans()
+		}
+	}
+}
+
+//reenqueueOrphans scans the store for Uack jobs that were actually
+//dispatched to a worker (DispatchedAt set) but aren't in q.inFlight, and
+//re-schedules them. It also reports queue-depth-by-status metrics,
+//piggybacking on the same scan.
+func (q *Queue) reenqueueOrphans() {
+	depth := map[JobStatus]int{}
+	err := q.store.View(func(tx Tx) error {
+		values, err := tx.List(jobsBucketName)
+		if err != nil {
+			return err
+		}
+		for _, v := range values {
+			job := DecodeJob(v)
+			depth[job.Status]++
+			if job.Status != Uack {
+				continue
+			}
+			if job.DispatchedAt.IsZero() || job.RunAt.After(time.Now()) {
+				// Never actually picked up by a worker yet (e.g. still
+				// waiting for RunAt in the pending heap) - not an orphan.
+				continue
+			}
+			q.inFlightMu.Lock()
+			_, active := q.inFlight[string(job.ID)]
+			q.inFlightMu.Unlock()
+			if active {
+				continue
+			}
+			logger().Printf("Re-enqueuing orphaned job %v", string(job.ID))
+			q.schedule(job.ID, time.Now(), job.Priority)
+		}
+		return nil
+	})
+	if err != nil {
+		logger().Printf("Unable to scan for orphaned jobs: %s", err)
+		return
+	}
+	for _, status := range []JobStatus{Uack, Nack, Ack, Failed, Blocked} {
+		q.Metrics.SetQueueDepth(status, depth[status])
+	}
+}
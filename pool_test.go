@@ -0,0 +1,68 @@
+package orcaq
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/OrcaTools/orcaq/memstore"
+)
+
+// countingWorker records how many of its DoWork calls were in flight at
+// once, so tests can assert RegisterWorkerPool's concurrency bound holds.
+type countingWorker struct {
+	id       string
+	inFlight int32
+	maxSeen  int32
+	calls    int32
+	target   int32
+	done     chan struct{}
+}
+
+func (w *countingWorker) ID() string { return w.id }
+
+func (w *countingWorker) DoWork(ctx context.Context, job *Job) error {
+	cur := atomic.AddInt32(&w.inFlight, 1)
+	for {
+		max := atomic.LoadInt32(&w.maxSeen)
+		if cur <= max || atomic.CompareAndSwapInt32(&w.maxSeen, max, cur) {
+			break
+		}
+	}
+	time.Sleep(20 * time.Millisecond)
+	atomic.AddInt32(&w.inFlight, -1)
+	if atomic.AddInt32(&w.calls, 1) == w.target {
+		close(w.done)
+	}
+	return nil
+}
+
+func TestRegisterWorkerPool_BoundsConcurrency(t *testing.T) {
+	q, err := InitWithStore(memstore.New())
+	if err != nil {
+		t.Fatalf("InitWithStore: %v", err)
+	}
+	defer q.Close()
+
+	const jobs = 6
+	const concurrency = 2
+	w := &countingWorker{id: "w1", target: jobs, done: make(chan struct{})}
+	q.RegisterWorkerPool(w, concurrency)
+
+	for i := 0; i < jobs; i++ {
+		if _, err := q.PushBytes([]byte("x")); err != nil {
+			t.Fatalf("PushBytes: %v", err)
+		}
+	}
+
+	select {
+	case <-w.done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for all jobs to be processed")
+	}
+
+	if max := atomic.LoadInt32(&w.maxSeen); max > concurrency {
+		t.Fatalf("worker pool let %d jobs run concurrently, want at most %d", max, concurrency)
+	}
+}
@@ -0,0 +1,108 @@
+// Package promexporter is a Prometheus-backed implementation of
+// orcaq.Metrics. Register it with a Queue via Queue.Metrics, and scrape the
+// registry passed to New (or the default Prometheus registry) as usual.
+package promexporter
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/OrcaTools/orcaq"
+)
+
+// Exporter is an orcaq.Metrics backed by Prometheus counters, a histogram
+// and gauges.
+type Exporter struct {
+	jobsPushed  prometheus.Counter
+	jobsAcked   prometheus.Counter
+	jobsNacked  prometheus.Counter
+	jobsFailed  prometheus.Counter
+	jobsRetried prometheus.Counter
+
+	jobDuration prometheus.Histogram
+
+	queueDepth prometheus.GaugeVec
+	inFlight   prometheus.GaugeVec
+}
+
+// New registers orcaq's metrics against reg and returns the resulting
+// Exporter. Pass prometheus.DefaultRegisterer to use the global registry.
+func New(reg prometheus.Registerer) (*Exporter, error) {
+	e := &Exporter{
+		jobsPushed: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "orcaq",
+			Name:      "jobs_pushed_total",
+			Help:      "Total number of jobs pushed to the queue.",
+		}),
+		jobsAcked: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "orcaq",
+			Name:      "jobs_acked_total",
+			Help:      "Total number of jobs that completed successfully.",
+		}),
+		jobsNacked: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "orcaq",
+			Name:      "jobs_nacked_total",
+			Help:      "Total number of recoverable worker errors reported.",
+		}),
+		jobsFailed: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "orcaq",
+			Name:      "jobs_failed_total",
+			Help:      "Total number of jobs that reached Failed.",
+		}),
+		jobsRetried: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "orcaq",
+			Name:      "jobs_retried_total",
+			Help:      "Total number of jobs rescheduled after a Nack.",
+		}),
+		jobDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: "orcaq",
+			Name:      "job_duration_seconds",
+			Help:      "Time from a job being picked up by a worker to reaching a terminal status.",
+			Buckets:   prometheus.DefBuckets,
+		}),
+		queueDepth: *prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "orcaq",
+			Name:      "queue_depth",
+			Help:      "Number of jobs currently holding each status.",
+		}, []string{"status"}),
+		inFlight: *prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "orcaq",
+			Name:      "in_flight_jobs",
+			Help:      "Number of jobs currently being processed, by worker ID.",
+		}, []string{"worker"}),
+	}
+
+	collectors := []prometheus.Collector{
+		e.jobsPushed, e.jobsAcked, e.jobsNacked, e.jobsFailed, e.jobsRetried,
+		e.jobDuration, &e.queueDepth, &e.inFlight,
+	}
+	for _, c := range collectors {
+		if err := reg.Register(c); err != nil {
+			return nil, err
+		}
+	}
+
+	return e, nil
+}
+
+func (e *Exporter) IncJobsPushed()  { e.jobsPushed.Inc() }
+func (e *Exporter) IncJobsAcked()   { e.jobsAcked.Inc() }
+func (e *Exporter) IncJobsNacked()  { e.jobsNacked.Inc() }
+func (e *Exporter) IncJobsFailed()  { e.jobsFailed.Inc() }
+func (e *Exporter) IncJobsRetried() { e.jobsRetried.Inc() }
+
+func (e *Exporter) ObserveJobDuration(d time.Duration) {
+	e.jobDuration.Observe(d.Seconds())
+}
+
+func (e *Exporter) SetQueueDepth(status orcaq.JobStatus, depth int) {
+	e.queueDepth.WithLabelValues(strconv.Itoa(int(status))).Set(float64(depth))
+}
+
+func (e *Exporter) SetInFlight(workerID string, count int) {
+	e.inFlight.WithLabelValues(workerID).Set(float64(count))
+}
+
+var _ orcaq.Metrics = (*Exporter)(nil)
@@ -2,147 +2,192 @@ package orcaq
 
 import (
 	"context"
-	"fmt"
 	"sync"
 	"time"
 
-	log "github.com/sirupsen/logrus"
 	"github.com/twinj/uuid"
 
-	"github.com/xujiajun/nutsdb"
+	"github.com/OrcaTools/orcaq/nutsdbstore"
+	"github.com/OrcaTools/orcaq/store"
 )
 
 const (
 	jobsBucketName = "Jobs"
+	//dedupBucketName maps a Job.DedupKey to the ID of the job currently
+	//holding that key, so PushJob can enforce at most one live job per key.
+	dedupBucketName = "DedupKeys"
+	//tombstoneBucketName maps the ID of a Job that processJobs has deleted
+	//(because it reached Ack or Failed) to a single byte holding its final
+	//JobStatus. Without this, PushJob can't tell a dependency that already
+	//completed from one that failed once its Jobs row is gone - see
+	//PushJob's dependency resolution.
+	tombstoneBucketName = "JobTombstones"
 )
 
+// Store is the persistence interface Queue uses for all job data. It is an
+// alias for store.Store so callers can refer to orcaq.Store without also
+// importing the store package. See nutsdbstore, memstore and sqlitestore for
+// implementations.
+type Store = store.Store
+
+// Tx is the set of operations available within a Store.Update or Store.View
+// callback. It is an alias for store.Tx.
+type Tx = store.Tx
+
 // Queue represents a queue
 type Queue struct {
 	//ID is a unique identifier for a Queue
 	ID string
-	//db represents a handle to a key/value store
-	db *nutsdb.DB
-	//notifier is a chan used to signal workers there is a job to begin working
+	//store is the persistence backend holding job data
+	store Store
+	//notifier is a chan used to signal the work dispatcher there is a job to
+	//begin working. It is fed by runDispatcher and drained by
+	//runWorkDispatcher.
 	notifier chan []byte
-	//workeres is a list of *Workers
-	workers []*Worker
+	//pools holds the registered worker pools, each bounding how many of its
+	//Worker's DoWork calls may run concurrently. Populated by
+	//RegisterWorker/RegisterWorkerPool and fanned out to by runWorkDispatcher.
+	pools []*workerPool
+	//poolsMu guards pools
+	poolsMu sync.Mutex
+	//inFlight tracks the IDs of jobs currently being processed by a worker
+	//pool, so runOrphanScan doesn't re-enqueue a Uack job that is simply still
+	//running.
+	inFlight map[string]struct{}
+	//inFlightMu guards inFlight
+	inFlightMu sync.Mutex
 	//shutdownFuncs are context.CancleFuncs used to signal graceful shutdown
 	shutdownFuncs []context.CancelFunc
 	//wg is used to help gracefully shutdown workers
 	wg *sync.WaitGroup
 
-	//PollRate the duration to Sleep each worker before checking the queue for jobs again
-	//queue for jobs again.
+	//pending is a min-heap of jobs that have been pushed but are not yet due,
+	//ordered by (RunAt, -Priority). It is populated by schedule and drained
+	//by runDispatcher.
+	pending pendingHeap
+	//scheduled tracks the IDs of jobs that are somewhere between schedule()
+	//and actually starting in runJob (queued in pending, or popped and
+	//sitting in notifier awaiting a free worker pool slot), so schedule
+	//doesn't push a second heap entry for a job that's already on its way
+	//to being dispatched. Guarded by pendingMu alongside pending.
+	scheduled map[string]struct{}
+	//pendingMu guards pending and scheduled
+	pendingMu sync.Mutex
+	//pendingWake wakes runDispatcher when a job is scheduled that may be due
+	//sooner than whatever it is currently waiting on
+	pendingWake chan struct{}
+
+	//dependants maps a dependency Job's ID (string) to the IDs (string) of
+	//Blocked Jobs waiting on it. Populated by addDependants and rebuilt from
+	//the bucket by processJobs on Init.
+	dependants map[string][]string
+	//dependantsMu guards dependants
+	dependantsMu sync.Mutex
+
+	//eventSubs maps a Job ID (string) to its SubscribeJobEvents registrations
+	eventSubs map[string][]*eventSub
+	//eventSubsMu guards eventSubs
+	eventSubsMu sync.Mutex
+
+	//PollRate is how often runOrphanScan checks for Uack jobs that aren't
+	//actually in flight (e.g. left behind by a worker that crashed) and
+	//re-enqueues them.
 	//Default: 500 milliseconds
 	PollRate time.Duration
+
+	//RetryPolicy is the default backoff/retry-limit policy applied to Nack'd
+	//jobs that don't set their own Job.RetryPolicy. The zero value retries
+	//immediately with no limit, matching the queue's original behavior.
+	RetryPolicy RetryPolicy
+
+	//Metrics receives counters/histograms/gauges for job lifecycle events.
+	//Defaults to a no-op implementation; see orcaq/promexporter.
+	Metrics Metrics
+
+	//Tracer wraps job dispatch and persistence in spans propagated via
+	//context.Context. Defaults to a no-op implementation.
+	Tracer Tracer
 }
 
-//Init creates a connection to the internal database and initializes the Queue type
-//filepath must be a valid path to a file. It cannot be shared between instances of
-//a Queue. If the  file cannot be opened r/w, an error is returned.
+//Init creates a connection to the internal nutsdb database and initializes
+//the Queue type. filepath must be a valid path to a file. It cannot be
+//shared between instances of a Queue. If the file cannot be opened r/w, an
+//error is returned. Init is a thin wrapper around InitWithStore for callers
+//who don't need a non-default Store.
 func Init(filepath string) (*Queue, error) {
-	q := &Queue{ID: filepath, PollRate: time.Duration(500 * time.Millisecond)}
-
-	// create a new db
-	opt := nutsdb.DefaultOptions
-	opt.Dir = filepath
-	db, err := nutsdb.Open(opt)
+	s, err := nutsdbstore.New(filepath)
 	if err != nil {
-		log.Print(err)
+		logger().Print(err)
 		return nil, err
 	}
-	q.db = db
+	q, err := InitWithStore(s)
+	if err != nil {
+		return nil, err
+	}
+	q.ID = filepath
+	return q, nil
+}
+
+//InitWithStore initializes the Queue type against an already-constructed
+//Store, e.g. one pointed at a shared SQL database. See Init for the common
+//case of an embedded, file-backed queue.
+func InitWithStore(s Store) (*Queue, error) {
+	q := &Queue{ID: uuid.NewV4().String(), PollRate: time.Duration(500 * time.Millisecond)}
+	q.store = s
 
 	// Make notification channels
 	c := make(chan []byte, 1000) //TODO: channel probably isn't the best way to handle the queue buffer
 	q.notifier = c
-	q.workers = make([]*Worker, 0)
+	q.pools = make([]*workerPool, 0)
+	q.inFlight = make(map[string]struct{})
 	q.shutdownFuncs = make([]context.CancelFunc, 0)
 	var wg sync.WaitGroup
 	q.wg = &wg
+	q.pending = make(pendingHeap, 0)
+	q.scheduled = make(map[string]struct{})
+	q.pendingWake = make(chan struct{}, 1)
+	q.dependants = make(map[string][]string)
+	q.eventSubs = make(map[string][]*eventSub)
+	q.Metrics = noopMetrics{}
+	q.Tracer = noopTracer{}
 
 	//resume stopped jobs, clean completed, failed jobs
-	err = q.processJobs()
+	err := q.processJobs()
 	if err != nil {
-		log.Printf("Unable to resume jobs from bucket: %s", err)
+		logger().Printf("Unable to resume jobs from bucket: %s", err)
 	}
+
+	//start the dispatcher that wakes idle workers once delayed/priority jobs come due
+	dispatchCtx, cancelFunc := context.WithCancel(context.Background())
+	q.shutdownFuncs = append(q.shutdownFuncs, cancelFunc)
+	q.wg.Add(1)
+	go q.runDispatcher(dispatchCtx)
+
+	//start the central work dispatcher that fans jobs out to worker pools
+	workCtx, workCancelFunc := context.WithCancel(context.Background())
+	q.shutdownFuncs = append(q.shutdownFuncs, workCancelFunc)
+	q.wg.Add(1)
+	go q.runWorkDispatcher(workCtx)
+
+	//start the periodic scan that re-enqueues orphaned Uack jobs
+	scanCtx, scanCancelFunc := context.WithCancel(context.Background())
+	q.shutdownFuncs = append(q.shutdownFuncs, scanCancelFunc)
+	q.wg.Add(1)
+	go q.runOrphanScan(scanCtx)
+
 	return q, nil
 }
 
-//Close attempts to gracefully shutdown all workers in a queue and shutdown the db connection
+//Close attempts to gracefully shutdown all workers in a queue and shutdown the store
 func (q *Queue) Close() error {
 	for _, f := range q.shutdownFuncs {
 		f()
 	}
 	q.wg.Wait()
 	q.notifier = nil
-	q.workers = nil
+	q.pools = nil
 	q.shutdownFuncs = nil
-	return q.db.Close()
-}
-
-//registerWorkerWithContext contains the main loop for all Workers.
-func (q *Queue) registerWorkerWithContext(ctx context.Context, w Worker) {
-	q.workers = append(q.workers, &w)
-	q.wg.Add(1)
-	log.Printf("Registering worker with ID: %s", w.ID())
-	//The big __main loop__ for workers.
-	go func() {
-		log.Printf("Starting up new worker...")
-		var jobID []byte
-		for {
-			// receive a notification from the queue chan
-			select {
-			case <-ctx.Done():
-				log.Printf("Received signal to shutdown worker. Exiting.")
-				q.wg.Done()
-				return
-			case jobID = <-q.notifier:
-				log.Printf("Received job id %v", string(jobID))
-				err := q.updateJobStatus(jobID, Uack, fmt.Sprintf("Picked up by %s", w.ID()))
-				if err != nil {
-					log.Printf("Unable to update job status: %s", err)
-					continue
-				}
-				//If subsequent calls to updateJobStatus fail, the whole thing is probably hosed and
-				//it should probably do something more drastic for error handling.
-				job, err := q.GetJobByID(jobID)
-				if err != nil {
-					log.Printf("Error processing job: %s", err)
-					q.updateJobStatus(jobID, Failed, err.Error())
-					continue
-				}
-				// Call the worker func handling this job
-				err = w.DoWork(ctx, job)
-				if err != nil {
-					_, ok := err.(RecoverableWorkerError)
-					if ok {
-						//temporary error, retry
-						log.Printf("Received temporary error: %s. Retrying...", err.Error())
-						q.updateJobStatus(jobID, Nack, err.Error())
-					} else {
-						log.Printf("Permanent error received from worker: %s", err)
-						//permanent error, mark as failed
-						q.updateJobStatus(jobID, Failed, err.Error())
-					}
-				} else {
-					q.updateJobStatus(jobID, Ack, "Complete")
-				}
-				log.Printf("Finished processing job %v", string(jobID))
-			default:
-				// log.Printf("Worker: %s. No message to queue. Sleeping 500ms", w.ID())
-				time.Sleep(q.PollRate)
-			}
-		}
-	}()
-}
-
-//RegisterWorker registers a Worker to handle queued Jobs
-func (q *Queue) RegisterWorker(w Worker) {
-	baseCtx := context.Background()
-	ctx, cancelFunc := context.WithCancel(baseCtx)
-	q.shutdownFuncs = append(q.shutdownFuncs, cancelFunc)
-	q.registerWorkerWithContext(ctx, w)
+	return q.store.Close()
 }
 
 //PushBytes wraps arbitrary binary data in a job and pushes it onto the queue
@@ -155,89 +200,357 @@ func (q *Queue) PushBytes(d []byte) ([]byte, error) {
 	return q.PushJob(job)
 }
 
-//PushJob pushes a job to the queue and notifies workers
-// Job.ID is always overwritten
+//PushJob pushes a job to the queue and schedules it for dispatch once its
+//RunAt time arrives. Job.ID is always overwritten. If RunAt is unset, the job
+//becomes eligible immediately.
+//
+//If j.DedupKey is set and a job with that key is already queued in Uack,
+//Nack or Blocked status, the existing job's ID is returned instead and no
+//new job is enqueued. See PushUnique.
+//
+//If j.Dependencies is set, the job is pushed with Status Blocked until every
+//dependency reaches Ack; it only becomes eligible for dispatch once
+//PendingDeps reaches zero. If any dependency has already Failed, the job is
+//pushed directly as Failed. See updateJobStatus.
 func (q *Queue) PushJob(j *Job) ([]byte, error) {
-	err := q.db.Update(func(tx *nutsdb.Tx) error {
+	if j.RunAt.IsZero() {
+		j.RunAt = time.Now()
+	}
+	var existingID []byte
+	var pendingDepIDs [][]byte
+	err := q.store.Update(func(tx Tx) error {
+		if j.DedupKey != "" {
+			if existingJobID, derr := tx.Get(dedupBucketName, []byte(j.DedupKey)); derr == nil {
+				if jv, jerr := tx.Get(jobsBucketName, existingJobID); jerr == nil {
+					existingJob := DecodeJob(jv)
+					if existingJob.Status == Uack || existingJob.Status == Nack || existingJob.Status == Blocked {
+						existingID = existingJob.ID
+						return nil
+					}
+				}
+			}
+		}
+
 		j.ID = []byte(uuid.NewV4().String())
-		log.Printf("Storing job %v for processing", string(j.ID))
-		err := tx.Put(jobsBucketName, j.ID, j.Bytes(), 0) // setting this to 0 means never expires.
-		// NOTE: we can support jobs with a ttl. If the ttl expires, then the job is removed from the queue.
-		return err
+
+		for _, depID := range j.Dependencies {
+			dv, derr := tx.Get(jobsBucketName, depID)
+			if derr != nil {
+				// The dependency's Jobs row is gone because a prior Init
+				// already deleted it for reaching a terminal status;
+				// consult its tombstone for which one. If even the
+				// tombstone is missing, depID never existed - fail safe
+				// rather than silently treat an unresolvable dependency as
+				// satisfied.
+				tv, terr := tx.Get(tombstoneBucketName, depID)
+				if terr != nil || JobStatus(tv[0]) == Failed {
+					j.Status = Failed
+					j.Message = dependencyFailedMessage
+				}
+				continue
+			}
+			dep := DecodeJob(dv)
+			switch dep.Status {
+			case Ack:
+				// satisfied, nothing to track
+			case Failed:
+				j.Status = Failed
+				j.Message = dependencyFailedMessage
+			default:
+				pendingDepIDs = append(pendingDepIDs, depID)
+			}
+		}
+		j.PendingDeps = len(pendingDepIDs)
+		if j.Status != Failed && j.PendingDeps > 0 {
+			j.Status = Blocked
+		}
+
+		logger().Printf("Storing job %v for processing", string(j.ID))
+		if err := tx.Put(jobsBucketName, j.ID, j.Bytes()); err != nil {
+			return err
+		}
+		if j.DedupKey != "" {
+			if err := tx.Put(dedupBucketName, []byte(j.DedupKey), j.ID); err != nil {
+				return err
+			}
+		}
+		return nil
 	})
 	if err != nil {
-		log.Printf("Unable to push job to queue: %s", err)
+		logger().Printf("Unable to push job to queue: %s", err)
 		return nil, err
 	}
-	q.notifier <- j.ID
+	if existingID != nil {
+		logger().Printf("Job with dedup key %q already queued as %v; skipping", j.DedupKey, string(existingID))
+		return existingID, nil
+	}
+	q.Metrics.IncJobsPushed()
+	if j.Status == Blocked {
+		q.addDependants(j.ID, pendingDepIDs)
+		return j.ID, nil
+	}
+	if j.Status == Failed {
+		q.Metrics.IncJobsFailed()
+		return j.ID, nil
+	}
+	q.schedule(j.ID, j.RunAt, j.Priority)
 	return j.ID, nil
 }
 
+//PushJobAt is a convenience wrapper around PushJob that sets j.RunAt so the
+//job is not dispatched until runAt.
+func (q *Queue) PushJobAt(j *Job, runAt time.Time) ([]byte, error) {
+	j.RunAt = runAt
+	return q.PushJob(j)
+}
+
+//PushUnique pushes a job carrying the given dedup key, guaranteeing at most
+//one job with that key is queued (Uack, Nack or Blocked) at a time. If a
+//matching job is already pending, its existing ID is returned instead of
+//enqueueing a new one. This is useful for events like "rebuild repo X" that
+//fire repeatedly but only need to run once.
+func (q *Queue) PushUnique(key string, data []byte) ([]byte, error) {
+	job := &Job{
+		Status:   Uack,
+		Data:     data,
+		DedupKey: key,
+	}
+	return q.PushJob(job)
+}
+
 //GetJobByID returns a pointer to a Job based on the primary key identifier id
 func (q *Queue) GetJobByID(id []byte) (*Job, error) {
 	var job *Job
-	err := q.db.View(func(tx *nutsdb.Tx) error {
-		e, err := tx.Get(jobsBucketName, id)
+	err := q.store.View(func(tx Tx) error {
+		v, err := tx.Get(jobsBucketName, id)
 		if err != nil {
 			return err
 		}
-		job = DecodeJob(e.Value)
+		job = DecodeJob(v)
 		return nil
 	})
 	return job, err
 }
 
-//updateJobStatus updates the processing status of a job
+//updateJobStatus updates the processing status of a job to Uack, Ack or
+//Failed. Nack'd jobs (i.e. retries) go through nackJob instead, since that
+//path also has to evaluate the RetryPolicy.
 func (q *Queue) updateJobStatus(id []byte, status JobStatus, message string) error {
-	err := q.db.Update(func(tx *nutsdb.Tx) error {
-		e, err := tx.Get(jobsBucketName, id)
+	var dispatchedAt time.Time
+	err := q.store.Update(func(tx Tx) error {
+		v, err := tx.Get(jobsBucketName, id)
 		if err != nil {
 			return err
 		}
-		job := DecodeJob(e.Value)
+		job := DecodeJob(v)
 		job.Status = status
 		job.Message = message
-		if status == Nack {
-			job.RetryCount++
+		if status == Uack && job.DispatchedAt.IsZero() {
+			job.DispatchedAt = time.Now()
 		}
-		return tx.Put(jobsBucketName, job.ID, job.Bytes(), 0)
+		dispatchedAt = job.DispatchedAt
+		if (status == Ack || status == Failed) && job.DedupKey != "" {
+			if derr := tx.Delete(dedupBucketName, []byte(job.DedupKey)); derr != nil {
+				logger().Printf("Unable to clear dedup mapping for key %q: %s", job.DedupKey, derr)
+			}
+		}
+		return tx.Put(jobsBucketName, job.ID, job.Bytes())
 	})
+	if err != nil {
+		return err
+	}
 
-	if status == Nack && err == nil {
-		q.notifier <- id
+	q.publishJobEvent(id, status, message)
+
+	switch status {
+	case Ack:
+		q.Metrics.IncJobsAcked()
+	case Failed:
+		q.Metrics.IncJobsFailed()
+	}
+	if (status == Ack || status == Failed) && !dispatchedAt.IsZero() {
+		q.Metrics.ObserveJobDuration(time.Since(dispatchedAt))
 	}
-	return err
+
+	if status == Ack || status == Failed {
+		q.dependantsMu.Lock()
+		dependants := q.dependants[string(id)]
+		delete(q.dependants, string(id))
+		q.dependantsMu.Unlock()
+
+		if status == Ack {
+			for _, dependantID := range dependants {
+				q.handleDependencyAck([]byte(dependantID))
+			}
+		} else {
+			for _, dependantID := range dependants {
+				q.updateJobStatus([]byte(dependantID), Failed, dependencyFailedMessage)
+			}
+		}
+	}
+
+	return nil
 }
 
-// processJobs loops through all jobs marked as completed or failed and deletes them from the database
-// Warning: this is destructive, that job data is definitely done if you call this function.
+//nackJob records a recoverable failure on a job and reschedules it according
+//to the effective RetryPolicy (the Job's own override, or the Queue's
+//default). retryAfter, if non-nil, overrides the policy's computed backoff.
+//Once the job's RetryCount reaches the policy's MaxRetries, it is instead
+//transitioned to Failed with a "max retries exceeded" message.
+func (q *Queue) nackJob(id []byte, message string, retryAfter *time.Duration) error {
+	var runAt time.Time
+	var priority int
+	var finalMessage string
+	var dispatchedAt time.Time
+	failed := false
+
+	err := q.store.Update(func(tx Tx) error {
+		v, err := tx.Get(jobsBucketName, id)
+		if err != nil {
+			return err
+		}
+		job := DecodeJob(v)
+		job.RetryCount++
+		job.Message = message
+		dispatchedAt = job.DispatchedAt
+
+		policy := q.RetryPolicy
+		if job.RetryPolicy != nil {
+			policy = *job.RetryPolicy
+		}
+
+		if policy.MaxRetries > 0 && job.RetryCount >= policy.MaxRetries {
+			job.Status = Failed
+			job.Message = "max retries exceeded"
+			failed = true
+			if job.DedupKey != "" {
+				if derr := tx.Delete(dedupBucketName, []byte(job.DedupKey)); derr != nil {
+					logger().Printf("Unable to clear dedup mapping for key %q: %s", job.DedupKey, derr)
+				}
+			}
+		} else {
+			delay := computeBackoff(policy, job.RetryCount)
+			if retryAfter != nil {
+				delay = *retryAfter
+			}
+			job.Status = Nack
+			job.RunAt = time.Now().Add(delay)
+			runAt = job.RunAt
+			priority = job.Priority
+		}
+		finalMessage = job.Message
+
+		return tx.Put(jobsBucketName, job.ID, job.Bytes())
+	})
+	if err != nil {
+		return err
+	}
+
+	q.Metrics.IncJobsNacked()
+
+	if failed {
+		q.Metrics.IncJobsFailed()
+		if !dispatchedAt.IsZero() {
+			q.Metrics.ObserveJobDuration(time.Since(dispatchedAt))
+		}
+		q.publishJobEvent(id, Failed, finalMessage)
+		q.dependantsMu.Lock()
+		dependants := q.dependants[string(id)]
+		delete(q.dependants, string(id))
+		q.dependantsMu.Unlock()
+		for _, dependantID := range dependants {
+			q.updateJobStatus([]byte(dependantID), Failed, dependencyFailedMessage)
+		}
+		return nil
+	}
+
+	q.Metrics.IncJobsRetried()
+	q.publishJobEvent(id, Nack, finalMessage)
+	q.schedule(id, runAt, priority)
+	return nil
+}
+
+//CancelJob marks a job as Failed with a "cancelled" message, preventing any
+//further dispatch or retry. It is a no-op error-wise if the job has already
+//reached a terminal status. A job a worker is already running is not
+//interrupted: the status that worker's result produces replaces the
+//cancelled status.
+func (q *Queue) CancelJob(id []byte) error {
+	return q.updateJobStatus(id, Failed, "cancelled")
+}
+
+// processJobs rebuilds the in-memory dispatch heap and dependants map from
+// jobs persisted in the store (Uack/Nack/Blocked), and deletes jobs that
+// already reached a terminal status.
+// Warning: the terminal-status deletion is destructive, that job data is
+// definitely done if you call this function.
 func (q *Queue) processJobs() error {
-	return q.db.Update(func(tx *nutsdb.Tx) error {
-		entries, err := tx.GetAll(jobsBucketName)
+	return q.store.Update(func(tx Tx) error {
+		values, err := tx.List(jobsBucketName)
 		if err != nil {
 			return err
 		}
-		for _, entry := range entries {
-			job := DecodeJob(entry.Value)
+
+		byID := make(map[string]*Job, len(values))
+		for _, v := range values {
+			job := DecodeJob(v)
+			byID[string(job.ID)] = job
+		}
+
+		for _, job := range byID {
 			switch job.Status {
-			case Uack:
-			case Nack:
-				break
+			case Uack, Nack:
+				q.schedule(job.ID, job.RunAt, job.Priority)
+			case Blocked:
+				failed := false
+				for _, depID := range job.Dependencies {
+					if dep, ok := byID[string(depID)]; ok && dep.Status == Failed {
+						failed = true
+						break
+					}
+				}
+				if failed {
+					// A dependency reached Failed in this same load; it'll be
+					// tombstoned and deleted below (possibly before or after
+					// this job, depending on map iteration order), so there
+					// will be no later event to fail this job on. Fail it
+					// now instead of leaving it Blocked forever, and don't
+					// bother registering it as a dependant of its other,
+					// now-irrelevant dependencies.
+					job.Status = Failed
+					job.Message = dependencyFailedMessage
+					if err := tx.Put(jobsBucketName, job.ID, job.Bytes()); err != nil {
+						return err
+					}
+					continue
+				}
+				for _, depID := range job.Dependencies {
+					if dep, ok := byID[string(depID)]; ok && dep.Status == Ack {
+						continue
+					}
+					q.addDependants(job.ID, [][]byte{depID})
+				}
 			case Failed:
+				if err := tx.Put(tombstoneBucketName, job.ID, []byte{byte(Failed)}); err != nil {
+					return err
+				}
 				err := tx.Delete(jobsBucketName, job.ID)
 				if err != nil {
-					log.Errorf("Unable to delete failed job %v from queue.", string(job.ID))
+					logger().Errorf("Unable to delete failed job %v from queue.", string(job.ID))
 					return err
 				}
-				log.Infof("removed failed job %v from queue", string(job.ID))
+				logger().Infof("removed failed job %v from queue", string(job.ID))
 				break
 			case Ack:
+				if err := tx.Put(tombstoneBucketName, job.ID, []byte{byte(Ack)}); err != nil {
+					return err
+				}
 				err := tx.Delete(jobsBucketName, job.ID)
 				if err != nil {
-					log.Errorf("Unable to delete completed job %v from queue.", string(job.ID))
+					logger().Errorf("Unable to delete completed job %v from queue.", string(job.ID))
 					return err
 				}
-				log.Infof("removed completed job %v from queue", string(job.ID))
+				logger().Infof("removed completed job %v from queue", string(job.ID))
 				break
 			}
 		}
@@ -248,13 +561,13 @@ func (q *Queue) processJobs() error {
 // ListJobs will return a list of jobs within the queue
 func (q *Queue) ListJobs() (map[string]interface{}, error) {
 	r := map[string]interface{}{}
-	err := q.db.View(func(tx *nutsdb.Tx) error {
-		entries, err := tx.GetAll(jobsBucketName)
+	err := q.store.View(func(tx Tx) error {
+		values, err := tx.List(jobsBucketName)
 		if err != nil {
 			return err
 		}
-		for _, entry := range entries {
-			job := DecodeJob(entry.Value)
+		for _, v := range values {
+			job := DecodeJob(v)
 			r[string(job.ID)] = job
 		}
 		return nil
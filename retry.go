@@ -0,0 +1,50 @@
+package orcaq
+
+import (
+	"math/rand"
+	"time"
+)
+
+// RetryPolicy controls how a Nack'd job is rescheduled: how many times it may
+// be retried and how long to wait before each attempt. The zero value retains
+// the queue's original behavior of retrying immediately with no retry limit.
+type RetryPolicy struct {
+	// MaxRetries is the number of Nacks a job may accumulate before it is
+	// transitioned to Failed with a "max retries exceeded" message. Zero (or
+	// negative) means unlimited retries.
+	MaxRetries int
+	// BaseBackoff is the unit of the exponential backoff: the delay after a
+	// job's Nth Nack is BaseBackoff * 2^N, so the first retry waits
+	// 2*BaseBackoff. Zero means retry immediately.
+	BaseBackoff time.Duration
+	// MaxBackoff caps the computed delay. Zero means uncapped.
+	MaxBackoff time.Duration
+	// Jitter, when true, applies full jitter: the actual delay is chosen
+	// uniformly at random between zero and the computed backoff.
+	Jitter bool
+}
+
+// computeBackoff returns the delay to wait before redispatching a job that
+// has just accumulated retryCount Nacks, per policy.
+func computeBackoff(policy RetryPolicy, retryCount int) time.Duration {
+	if policy.BaseBackoff <= 0 {
+		return 0
+	}
+
+	delay := policy.BaseBackoff * time.Duration(uint64(1)<<uint(retryCount))
+	if policy.MaxBackoff > 0 && delay > policy.MaxBackoff {
+		delay = policy.MaxBackoff
+	}
+	if delay <= 0 {
+		// overflowed from the shift; fall back to the cap (or base if uncapped)
+		delay = policy.MaxBackoff
+		if delay <= 0 {
+			delay = policy.BaseBackoff
+		}
+	}
+
+	if policy.Jitter {
+		delay = time.Duration(rand.Int63n(int64(delay) + 1))
+	}
+	return delay
+}
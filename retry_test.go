@@ -0,0 +1,69 @@
+package orcaq
+
+import (
+	"testing"
+	"time"
+
+	"github.com/OrcaTools/orcaq/memstore"
+)
+
+func TestComputeBackoff_ExponentialWithCap(t *testing.T) {
+	policy := RetryPolicy{BaseBackoff: time.Second, MaxBackoff: 10 * time.Second}
+
+	cases := []struct {
+		retryCount int
+		want       time.Duration
+	}{
+		{1, 2 * time.Second},
+		{2, 4 * time.Second},
+		{3, 8 * time.Second},
+		{4, 10 * time.Second}, // would be 16s uncapped
+	}
+	for _, c := range cases {
+		if got := computeBackoff(policy, c.retryCount); got != c.want {
+			t.Errorf("computeBackoff(retryCount=%d) = %v, want %v", c.retryCount, got, c.want)
+		}
+	}
+}
+
+func TestComputeBackoff_ZeroBaseMeansNoDelay(t *testing.T) {
+	if got := computeBackoff(RetryPolicy{}, 3); got != 0 {
+		t.Errorf("expected zero BaseBackoff to retry immediately, got %v", got)
+	}
+}
+
+func TestNackJob_FailsAfterMaxRetries(t *testing.T) {
+	q, err := InitWithStore(memstore.New())
+	if err != nil {
+		t.Fatalf("InitWithStore: %v", err)
+	}
+	defer q.Close()
+	q.RetryPolicy = RetryPolicy{MaxRetries: 2}
+
+	id, err := q.PushBytes([]byte("payload"))
+	if err != nil {
+		t.Fatalf("PushBytes: %v", err)
+	}
+
+	if err := q.nackJob(id, "transient", nil); err != nil {
+		t.Fatalf("nackJob: %v", err)
+	}
+	job, err := q.GetJobByID(id)
+	if err != nil {
+		t.Fatalf("GetJobByID: %v", err)
+	}
+	if job.Status != Nack {
+		t.Fatalf("expected job to still be retryable after 1 of 2 retries, got %v", job.Status)
+	}
+
+	if err := q.nackJob(id, "transient", nil); err != nil {
+		t.Fatalf("nackJob: %v", err)
+	}
+	job, err = q.GetJobByID(id)
+	if err != nil {
+		t.Fatalf("GetJobByID: %v", err)
+	}
+	if job.Status != Failed {
+		t.Fatalf("expected job to fail once MaxRetries is reached, got %v", job.Status)
+	}
+}
@@ -0,0 +1,132 @@
+package orcaq
+
+import (
+	"container/heap"
+	"context"
+	"time"
+)
+
+// pendingJob is an entry in the dispatcher's min-heap of jobs awaiting dispatch
+type pendingJob struct {
+	id       []byte
+	runAt    time.Time
+	priority int
+}
+
+// pendingHeap orders pendingJobs by (runAt, -priority): the job due soonest
+// sorts first, and among jobs due at the same time the highest Priority wins.
+type pendingHeap []*pendingJob
+
+func (h pendingHeap) Len() int { return len(h) }
+
+func (h pendingHeap) Less(i, j int) bool {
+	if h[i].runAt.Equal(h[j].runAt) {
+		return h[i].priority > h[j].priority
+	}
+	return h[i].runAt.Before(h[j].runAt)
+}
+
+func (h pendingHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *pendingHeap) Push(x interface{}) {
+	*h = append(*h, x.(*pendingJob))
+}
+
+func (h *pendingHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	*h = old[:n-1]
+	return item
+}
+
+// schedule adds a job to the dispatcher's pending heap and wakes the
+// dispatcher so it can re-evaluate the next due time. It is a no-op if id is
+// already scheduled (queued in pending, or already popped and waiting on a
+// worker pool) - callers that don't track that themselves, like
+// reenqueueOrphans, can call it repeatedly without creating duplicate heap
+// entries that would otherwise each be dispatched to a worker independently.
+func (q *Queue) schedule(id []byte, runAt time.Time, priority int) {
+	q.pendingMu.Lock()
+	if _, already := q.scheduled[string(id)]; already {
+		q.pendingMu.Unlock()
+		return
+	}
+	q.scheduled[string(id)] = struct{}{}
+	heap.Push(&q.pending, &pendingJob{id: id, runAt: runAt, priority: priority})
+	q.pendingMu.Unlock()
+
+	select {
+	case q.pendingWake <- struct{}{}:
+	default:
+	}
+}
+
+// unschedule clears id's entry in scheduled, allowing a future schedule call
+// for the same ID to take effect again. Called once the job actually starts
+// running (see runJob), since reenqueueOrphans separately guards against
+// re-enqueuing anything already in q.inFlight from that point on.
+func (q *Queue) unschedule(id []byte) {
+	q.pendingMu.Lock()
+	delete(q.scheduled, string(id))
+	q.pendingMu.Unlock()
+}
+
+// runDispatcher sleeps until the next due job in the pending heap and then
+// sends its ID to q.notifier for an idle worker to pick up. It exits once
+// ctx is cancelled.
+func (q *Queue) runDispatcher(ctx context.Context) {
+	defer q.wg.Done()
+
+	timer := time.NewTimer(time.Hour)
+	if !timer.Stop() {
+		<-timer.C
+	}
+
+	for {
+		q.pendingMu.Lock()
+		var next *pendingJob
+		if len(q.pending) > 0 {
+			next = q.pending[0]
+		}
+		q.pendingMu.Unlock()
+
+		if next == nil {
+			select {
+			case <-ctx.Done():
+				return
+			case <-q.pendingWake:
+				continue
+			}
+		}
+
+		d := time.Until(next.runAt)
+		if d < 0 {
+			d = 0
+		}
+		timer.Reset(d)
+
+		select {
+		case <-ctx.Done():
+			if !timer.Stop() {
+				<-timer.C
+			}
+			return
+		case <-q.pendingWake:
+			if !timer.Stop() {
+				<-timer.C
+			}
+			continue
+		case <-timer.C:
+			q.pendingMu.Lock()
+			var job *pendingJob
+			if len(q.pending) > 0 {
+				job = heap.Pop(&q.pending).(*pendingJob)
+			}
+			q.pendingMu.Unlock()
+			if job != nil {
+				q.notifier <- job.id
+			}
+		}
+	}
+}
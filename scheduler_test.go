@@ -0,0 +1,69 @@
+package orcaq
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/OrcaTools/orcaq/memstore"
+)
+
+// callCountingWorker just counts how many times DoWork is invoked.
+type callCountingWorker struct {
+	id    string
+	calls int32
+}
+
+func (w *callCountingWorker) ID() string { return w.id }
+
+func (w *callCountingWorker) DoWork(ctx context.Context, job *Job) error {
+	atomic.AddInt32(&w.calls, 1)
+	return nil
+}
+
+// TestReenqueueOrphans_DoesNotDuplicateDispatch guards against
+// reenqueueOrphans pushing a second pending-heap entry for a job that is
+// already due but hasn't reached q.inFlight yet (e.g. because no worker pool
+// is registered for it yet). Without a "already scheduled" check in
+// schedule, each PollRate tick adds another heap entry, and every one of
+// them is independently dispatched to a worker once it registers.
+func TestReenqueueOrphans_DoesNotDuplicateDispatch(t *testing.T) {
+	// PollRate can't be changed race-free once InitWithStore has started
+	// runOrphanScan, so this relies on the default (500ms) and just waits
+	// long enough for it to tick a few times.
+	q, err := InitWithStore(memstore.New())
+	if err != nil {
+		t.Fatalf("InitWithStore: %v", err)
+	}
+	defer q.Close()
+
+	id, err := q.PushBytes([]byte("x"))
+	if err != nil {
+		t.Fatalf("PushBytes: %v", err)
+	}
+	// Simulate the job having already been picked up by a worker in a prior
+	// process (DispatchedAt set) before this Queue's worker pool is ready,
+	// so reenqueueOrphans considers it an orphan on every tick.
+	if err := q.updateJobStatus(id, Uack, "picked up by a worker that crashed"); err != nil {
+		t.Fatalf("updateJobStatus: %v", err)
+	}
+
+	// Leave the job pool-less for several PollRate ticks before registering
+	// a worker, giving reenqueueOrphans room to (mis)fire repeatedly.
+	time.Sleep(1100 * time.Millisecond)
+
+	w := &callCountingWorker{id: "w1"}
+	q.RegisterWorkerPool(w, 1)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for atomic.LoadInt32(&w.calls) == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	// Give any erroneous duplicate dispatch a further beat to show up.
+	time.Sleep(500 * time.Millisecond)
+
+	if calls := atomic.LoadInt32(&w.calls); calls != 1 {
+		t.Fatalf("expected DoWork to run exactly once for the single job, got %d calls", calls)
+	}
+}
@@ -0,0 +1,184 @@
+// Package server exposes an orcaq.Queue over HTTP, so producers and
+// operators don't need in-process access to the queue's store. See
+// orcaq/client for a symmetrical client implementing the same surface.
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/OrcaTools/orcaq"
+)
+
+// Server wraps a Queue with an http.Handler exposing it over HTTP.
+type Server struct {
+	q      *orcaq.Queue
+	logger orcaq.Logger
+}
+
+// New returns a Server fronting q. It logs through the same Logger q does.
+func New(q *orcaq.Queue) *Server {
+	return &Server{q: q, logger: orcaq.CurrentLogger()}
+}
+
+// Handler returns the http.Handler implementing the control-plane routes:
+//
+//	POST   /jobs            push a job, returns its ID
+//	GET    /jobs             list all jobs
+//	GET    /jobs/{id}        fetch a single job
+//	DELETE /jobs/{id}        cancel a job (marks it Failed)
+//	GET    /jobs/{id}/events SSE stream of status transitions
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/jobs", s.handleJobs)
+	mux.HandleFunc("/jobs/", s.handleJob)
+	return mux
+}
+
+// pushRequest is the JSON body accepted by POST /jobs.
+type pushRequest struct {
+	Data     []byte     `json:"data"`
+	RunAt    *time.Time `json:"run_at,omitempty"`
+	Priority int        `json:"priority,omitempty"`
+	DedupKey string     `json:"dedup_key,omitempty"`
+}
+
+func (s *Server) handleJobs(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		s.pushJob(w, r)
+	case http.MethodGet:
+		s.listJobs(w, r)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *Server) pushJob(w http.ResponseWriter, r *http.Request) {
+	var req pushRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, fmt.Sprintf("invalid request body: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	var id []byte
+	var err error
+	switch {
+	case req.DedupKey != "":
+		id, err = s.q.PushUnique(req.DedupKey, req.Data)
+	case req.RunAt != nil:
+		id, err = s.q.PushJobAt(&orcaq.Job{Data: req.Data, Priority: req.Priority}, *req.RunAt)
+	default:
+		id, err = s.q.PushJob(&orcaq.Job{Data: req.Data, Priority: req.Priority})
+	}
+	if err != nil {
+		s.logger.Printf("Unable to push job: %s", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.writeJSON(w, http.StatusCreated, map[string]string{"id": string(id)})
+}
+
+func (s *Server) listJobs(w http.ResponseWriter, r *http.Request) {
+	jobs, err := s.q.ListJobs()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	s.writeJSON(w, http.StatusOK, jobs)
+}
+
+// handleJob routes /jobs/{id} and /jobs/{id}/events.
+func (s *Server) handleJob(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/jobs/")
+	if path == "" {
+		http.NotFound(w, r)
+		return
+	}
+
+	if strings.HasSuffix(path, "/events") {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		id := strings.TrimSuffix(path, "/events")
+		s.streamEvents(w, r, []byte(id))
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		s.getJob(w, r, []byte(path))
+	case http.MethodDelete:
+		s.cancelJob(w, r, []byte(path))
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *Server) getJob(w http.ResponseWriter, r *http.Request, id []byte) {
+	job, err := s.q.GetJobByID(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	s.writeJSON(w, http.StatusOK, job)
+}
+
+func (s *Server) cancelJob(w http.ResponseWriter, r *http.Request, id []byte) {
+	if err := s.q.CancelJob(id); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// streamEvents writes JobEvents for id to w as they happen, using
+// Server-Sent Events. The stream ends once the job reaches a terminal
+// status or the client disconnects.
+func (s *Server) streamEvents(w http.ResponseWriter, r *http.Request, id []byte) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	events, cancel := s.q.SubscribeJobEvents(id)
+	defer cancel()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			payload, err := json.Marshal(event)
+			if err != nil {
+				s.logger.Printf("Unable to marshal job event: %s", err)
+				continue
+			}
+			fmt.Fprintf(w, "data: %s\n\n", payload)
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+func (s *Server) writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		s.logger.Printf("Unable to write JSON response: %s", err)
+	}
+}
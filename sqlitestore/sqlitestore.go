@@ -0,0 +1,160 @@
+// Package sqlitestore is a SQLite-backed orcaq.Store implementation. Because
+// the database lives in a single file rather than an in-process map, it can
+// be shared by multiple orcaq processes pointed at the same path.
+package sqlitestore
+
+import (
+	"database/sql"
+	"fmt"
+
+	_ "github.com/mattn/go-sqlite3"
+
+	"github.com/OrcaTools/orcaq/store"
+)
+
+const schema = `
+CREATE TABLE IF NOT EXISTS store_entries (
+	bucket TEXT NOT NULL,
+	key    BLOB NOT NULL,
+	value  BLOB NOT NULL,
+	PRIMARY KEY (bucket, key)
+);
+`
+
+// sqliteStore is a store.Store backed by a SQLite database file.
+type sqliteStore struct {
+	db *sql.DB
+}
+
+// New opens (creating if necessary) a SQLite database at path and returns it
+// as a store.Store.
+//
+// orcaq's dispatcher, worker pool and orphan scanner all call Update
+// concurrently from separate goroutines, but SQLite only allows one writer
+// at a time; without tuning, the losing side of that race returns
+// SQLITE_BUSY instead of waiting. New sets a busy_timeout so those writers
+// block and retry instead of erroring, and caps the connection pool to 1 so
+// database/sql can't hand out a second connection that would just contend
+// for the same file lock.
+func New(path string) (store.Store, error) {
+	db, err := sql.Open("sqlite3", path+"?_busy_timeout=5000")
+	if err != nil {
+		return nil, err
+	}
+	db.SetMaxOpenConns(1)
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &sqliteStore{db: db}, nil
+}
+
+func (s *sqliteStore) Get(bucket string, key []byte) ([]byte, error) {
+	return get(s.db, bucket, key)
+}
+
+func (s *sqliteStore) Put(bucket string, key []byte, value []byte) error {
+	return put(s.db, bucket, key, value)
+}
+
+func (s *sqliteStore) Delete(bucket string, key []byte) error {
+	return del(s.db, bucket, key)
+}
+
+func (s *sqliteStore) List(bucket string) ([][]byte, error) {
+	return list(s.db, bucket)
+}
+
+func (s *sqliteStore) Update(fn func(tx store.Tx) error) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	if err := fn(&sqliteTx{tx: tx}); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *sqliteStore) View(fn func(tx store.Tx) error) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	return fn(&sqliteTx{tx: tx})
+}
+
+func (s *sqliteStore) Close() error {
+	return s.db.Close()
+}
+
+// queryer is satisfied by both *sql.DB and *sql.Tx, letting get/put/del/list
+// be shared between the non-transactional Store methods and sqliteTx.
+type queryer interface {
+	QueryRow(query string, args ...interface{}) *sql.Row
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+func get(q queryer, bucket string, key []byte) ([]byte, error) {
+	var value []byte
+	row := q.QueryRow(`SELECT value FROM store_entries WHERE bucket = ? AND key = ?`, bucket, key)
+	if err := row.Scan(&value); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("sqlitestore: key %q not found in bucket %q", key, bucket)
+		}
+		return nil, err
+	}
+	return value, nil
+}
+
+func put(q queryer, bucket string, key []byte, value []byte) error {
+	_, err := q.Exec(`INSERT INTO store_entries (bucket, key, value) VALUES (?, ?, ?)
+		ON CONFLICT(bucket, key) DO UPDATE SET value = excluded.value`, bucket, key, value)
+	return err
+}
+
+func del(q queryer, bucket string, key []byte) error {
+	_, err := q.Exec(`DELETE FROM store_entries WHERE bucket = ? AND key = ?`, bucket, key)
+	return err
+}
+
+func list(q queryer, bucket string) ([][]byte, error) {
+	rows, err := q.Query(`SELECT value FROM store_entries WHERE bucket = ?`, bucket)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var values [][]byte
+	for rows.Next() {
+		var v []byte
+		if err := rows.Scan(&v); err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+	return values, rows.Err()
+}
+
+// sqliteTx adapts a *sql.Tx to the store.Tx interface
+type sqliteTx struct {
+	tx *sql.Tx
+}
+
+func (t *sqliteTx) Get(bucket string, key []byte) ([]byte, error) {
+	return get(t.tx, bucket, key)
+}
+
+func (t *sqliteTx) Put(bucket string, key []byte, value []byte) error {
+	return put(t.tx, bucket, key, value)
+}
+
+func (t *sqliteTx) Delete(bucket string, key []byte) error {
+	return del(t.tx, bucket, key)
+}
+
+func (t *sqliteTx) List(bucket string) ([][]byte, error) {
+	return list(t.tx, bucket)
+}
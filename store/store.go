@@ -0,0 +1,33 @@
+// Package store defines the persistence interface orcaq.Queue uses to hold
+// job data, so the backing database can be swapped without touching queue
+// logic. See the nutsdbstore, memstore and sqlitestore subpackages for
+// implementations.
+package store
+
+// Store is a bucketed key/value store with transactional Update/View.
+type Store interface {
+	// Get returns the value stored under bucket/key, or an error if absent.
+	Get(bucket string, key []byte) ([]byte, error)
+	// Put stores value under bucket/key, creating bucket if needed.
+	Put(bucket string, key []byte, value []byte) error
+	// Delete removes bucket/key. Deleting an absent key is not an error.
+	Delete(bucket string, key []byte) error
+	// List returns every value currently stored in bucket.
+	List(bucket string) ([][]byte, error)
+	// Update runs fn in a single atomic unit of work. If fn returns an error,
+	// any writes made through tx are rolled back.
+	Update(fn func(tx Tx) error) error
+	// View runs fn in a single read-only unit of work.
+	View(fn func(tx Tx) error) error
+	// Close releases any resources held by the Store.
+	Close() error
+}
+
+// Tx is the set of operations available within a Store.Update or Store.View
+// callback.
+type Tx interface {
+	Get(bucket string, key []byte) ([]byte, error)
+	Put(bucket string, key []byte, value []byte) error
+	Delete(bucket string, key []byte) error
+	List(bucket string) ([][]byte, error)
+}
@@ -0,0 +1,32 @@
+package orcaq
+
+import "context"
+
+// Span is a started trace span, as returned by Tracer.Start. It mirrors
+// OpenTelemetry's trace.Span closely enough that an OpenTelemetry-backed
+// Tracer needs only to wrap a span in this interface.
+type Span interface {
+	End()
+}
+
+// Tracer lets callers observe spans around job dispatch and persistence,
+// propagated via context.Context alongside the job as it moves from
+// dispatch through to the Worker's DoWork. A Queue created by Init or
+// InitWithStore uses noopTracer, so wiring in a real Tracer (e.g. an OpenTelemetry
+// implementation) is opt-in.
+type Tracer interface {
+	// Start begins a span named name as a child of any span already present
+	// in ctx, and returns a context carrying the new span alongside it.
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+// noopTracer is the default Tracer; it produces spans that do nothing.
+type noopTracer struct{}
+
+func (noopTracer) Start(ctx context.Context, name string) (context.Context, Span) {
+	return ctx, noopSpan{}
+}
+
+type noopSpan struct{}
+
+func (noopSpan) End() {}
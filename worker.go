@@ -0,0 +1,29 @@
+package orcaq
+
+import (
+	"context"
+	"time"
+)
+
+// Worker processes Jobs pulled off a Queue
+type Worker interface {
+	// ID returns a unique identifier for this Worker, used in logging
+	ID() string
+	// DoWork is called with the Job picked up for processing. Returning a
+	// RecoverableWorkerError causes the Job to be Nack'd and retried; any
+	// other error marks the Job Failed.
+	DoWork(ctx context.Context, job *Job) error
+}
+
+// RecoverableWorkerError indicates a transient failure that should result in
+// the Job being retried rather than marked Failed
+type RecoverableWorkerError struct {
+	Err error
+	// RetryAfter, when non-zero, overrides the Queue/Job RetryPolicy's
+	// computed backoff for this particular retry.
+	RetryAfter time.Duration
+}
+
+func (e RecoverableWorkerError) Error() string {
+	return e.Err.Error()
+}